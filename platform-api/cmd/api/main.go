@@ -11,11 +11,15 @@ import (
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/mtk14m/notilius/platform-api/internal/clients"
 	"github.com/mtk14m/notilius/platform-api/internal/config"
+	"github.com/mtk14m/notilius/platform-api/internal/controlapi"
 	"github.com/mtk14m/notilius/platform-api/internal/middleware"
+	"github.com/mtk14m/notilius/platform-api/internal/observability/tracing"
 )
 
 func main() {
+	ctx := context.Background()
 
 	//Load config
 	cfg := config.Load()
@@ -25,15 +29,72 @@ func main() {
 	}
 
 	//Setup du looger avec la config
-	setupLogger(cfg.Observability.LogLevel, cfg.Observability.LogFormat)
+	logLevel := setupLogger(cfg.Observability.LogLevel, cfg.Observability.LogFormat)
 	slog.Info("Starting platform-api", "version", "1.0.0")
 
+	provisioningClient, err := clients.NewProvisioningClient(cfg.ProvisioningService)
+	if err != nil {
+		slog.Error("Failed to create provisioning client", "error", err)
+		os.Exit(1)
+	}
+	defer provisioningClient.Close()
+
+	controlServer := controlapi.NewServer(cfg, logLevel)
+	controlServer.RegisterClient(provisioningClient.Name(), provisioningClient, provisioningClient)
+
+	// Hot-reload the log level and the control API's GET /debug/config
+	// snapshot when CONFIG_FILE changes on disk. No-op if the config wasn't
+	// loaded from a file. Log format changes still need a restart since the
+	// handler itself isn't rebuilt.
+	stopConfigWatch, err := cfg.OnChange(func(updated *config.Config) {
+		logLevel.Set(parseLogLevel(updated.Observability.LogLevel))
+		controlServer.UpdateConfig(updated)
+		slog.Info("Configuration reloaded", "logLevel", updated.Observability.LogLevel)
+	})
+	if err != nil {
+		slog.Warn("Config hot-reload disabled", "error", err)
+	} else {
+		defer stopConfigWatch()
+	}
+
+	controlSrv, err := controlServer.Listen()
+	if err != nil {
+		slog.Error("Failed to start control API", "error", err)
+		os.Exit(1)
+	}
+	go func() {
+		if err := controlSrv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			slog.Error("Control API server failed", "error", err)
+		}
+	}()
+	defer func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		_ = controlSrv.Shutdown(ctx)
+	}()
+
+	tracerProvider, err := tracing.NewProvider(ctx, cfg.Observability)
+	if err != nil {
+		slog.Error("Failed to initialize tracing", "error", err)
+		os.Exit(1)
+	}
+
 	//Router
 	router := gin.Default()
 
 	// Middleware: Error handler (must be first to catch all errors)
 	router.Use(middleware.ErrorHandlerMiddleware())
 
+	// Middleware: Tracing (extracts traceparent, starts the server span)
+	router.Use(middleware.TracingMiddleware(cfg.Observability.TracingServiceName))
+
+	// Middleware: fallback trace ID correlation when tracing is disabled
+	router.Use(middleware.TraceIDMiddleware())
+
+	// Middleware: records each request in the control API's recent-traces
+	// ring buffer (GET /debug/traces/recent)
+	router.Use(controlServer.TraceRecorderMiddleware())
+
 	// Middleware: Request logging
 	router.Use(requestLoggingMiddleware())
 
@@ -49,6 +110,7 @@ func main() {
 
 	// API v1 routes
 	v1 := router.Group("/api/v1")
+	v1.Use(middleware.OIDCAuth(cfg.Auth))
 	{
 		v1.GET("/status", func(c *gin.Context) {
 			c.JSON(http.StatusOK, gin.H{
@@ -85,30 +147,33 @@ func main() {
 
 	slog.Info("Shutting down server...")
 
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 
-	if err := srv.Shutdown(ctx); err != nil {
+	if err := srv.Shutdown(shutdownCtx); err != nil {
 		slog.Error("Server forced to shutdown", "error", err)
 		os.Exit(1)
 	}
 
+	// Separate deadline from the HTTP drain above: if draining in-flight
+	// requests eats most of its budget, the tracer provider still gets a
+	// full window to flush batched spans instead of whatever is left over.
+	traceShutdownCtx, traceCancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer traceCancel()
+
+	if err := tracerProvider.Shutdown(traceShutdownCtx); err != nil {
+		slog.Error("Failed to shut down tracer provider", "error", err)
+	}
+
 	slog.Info("Server exited gracefully")
 }
 
-// setupLogger configures slog based on config
-func setupLogger(level, format string) {
-	var logLevel slog.Level
-	switch level {
-	case "debug":
-		logLevel = slog.LevelDebug
-	case "info":
-		logLevel = slog.LevelInfo
-	case "error":
-		logLevel = slog.LevelError
-	default:
-		logLevel = slog.LevelInfo
-	}
+// setupLogger configures slog based on config and returns the LevelVar
+// backing it, so callers (e.g. the control API's POST /debug/loglevel) can
+// change verbosity at runtime without recreating the handler.
+func setupLogger(level, format string) *slog.LevelVar {
+	logLevel := &slog.LevelVar{}
+	logLevel.Set(parseLogLevel(level))
 
 	var handler slog.Handler
 	if format == "json" {
@@ -121,43 +186,45 @@ func setupLogger(level, format string) {
 		})
 	}
 
-	slog.SetDefault(slog.New(handler))
+	slog.SetDefault(slog.New(tracing.NewSlogHandler(handler)))
+	return logLevel
+}
+
+func parseLogLevel(level string) slog.Level {
+	switch level {
+	case "debug":
+		return slog.LevelDebug
+	case "info":
+		return slog.LevelInfo
+	case "warn":
+		return slog.LevelWarn
+	case "error":
+		return slog.LevelError
+	default:
+		return slog.LevelInfo
+	}
 }
 
-// requestLoggingMiddleware logs HTTP requests
+// requestLoggingMiddleware logs HTTP requests. When tracing is enabled,
+// TracingMiddleware (mounted before this one) puts the request's span on the
+// request context, so the slog handler picks up trace_id/span_id on its own.
+// With tracing disabled there is no valid span, and these records carry no
+// trace ID; the fallback ID from TraceIDMiddleware is not logged here.
 func requestLoggingMiddleware() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		start := time.Now()
 
-		// Generate trace ID if not present
-		traceID := c.GetHeader("X-Trace-ID")
-		if traceID == "" {
-			traceID = randomString(16)
-		}
-		c.Set("traceId", traceID)
-		c.Header("X-Trace-ID", traceID)
-
 		// Process request
 		c.Next()
 
 		// Log request
 		duration := time.Since(start)
-		slog.Info("HTTP request",
+		slog.InfoContext(c.Request.Context(), "HTTP request",
 			"method", c.Request.Method,
 			"path", c.Request.URL.Path,
 			"status", c.Writer.Status(),
 			"duration_ms", duration.Milliseconds(),
-			"trace_id", traceID,
 			"ip", c.ClientIP(),
 		)
 	}
 }
-
-func randomString(length int) string {
-	const charset = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789"
-	b := make([]byte, length)
-	for i := range b {
-		b[i] = charset[time.Now().UnixNano()%int64(len(charset))]
-	}
-	return string(b)
-}
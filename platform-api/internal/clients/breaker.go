@@ -0,0 +1,110 @@
+package clients
+
+import (
+	"sync"
+	"time"
+)
+
+// breakerState is the classic closed/open/half-open circuit breaker state
+// machine: closed lets requests through, open rejects them outright until
+// the cooldown elapses, half-open lets requests through until the first
+// recorded result decides whether to close again or reopen. The number of
+// requests admitted while half-open is not limited to one.
+type breakerState int
+
+const (
+	breakerClosed breakerState = iota
+	breakerOpen
+	breakerHalfOpen
+)
+
+func (s breakerState) String() string {
+	switch s {
+	case breakerOpen:
+		return "open"
+	case breakerHalfOpen:
+		return "half-open"
+	default:
+		return "closed"
+	}
+}
+
+// circuitBreaker trips to open after fails (consecutive failures) reaches
+// failThreshold, and moves to half-open once the cooldown window has passed.
+type circuitBreaker struct {
+	mu            sync.Mutex
+	state         breakerState
+	fails         int
+	failThreshold int
+	cooldown      time.Duration
+	openedAt      time.Time
+	lastFailureAt time.Time
+}
+
+func newCircuitBreaker(failThreshold int, cooldown time.Duration) *circuitBreaker {
+	return &circuitBreaker{failThreshold: failThreshold, cooldown: cooldown}
+}
+
+// Allow reports whether a request may proceed, transitioning open->half-open
+// once the cooldown has elapsed.
+func (b *circuitBreaker) Allow() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.state != breakerOpen {
+		return true
+	}
+	if time.Since(b.openedAt) < b.cooldown {
+		return false
+	}
+	b.state = breakerHalfOpen
+	return true
+}
+
+// RecordSuccess closes the breaker and resets the failure count.
+func (b *circuitBreaker) RecordSuccess() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.state = breakerClosed
+	b.fails = 0
+}
+
+// RecordFailure trips the breaker open if the half-open probe failed, or if
+// consecutive failures reached failThreshold.
+func (b *circuitBreaker) RecordFailure() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.fails++
+	b.lastFailureAt = time.Now()
+	if b.state == breakerHalfOpen || b.fails >= b.failThreshold {
+		b.state = breakerOpen
+		b.openedAt = time.Now()
+	}
+}
+
+// Reset forces the breaker back to closed, used by the control API's
+// POST /debug/circuit/{service}/reset.
+func (b *circuitBreaker) Reset() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.state = breakerClosed
+	b.fails = 0
+}
+
+func (b *circuitBreaker) State() breakerState {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.state
+}
+
+// Stats returns the current consecutive-failure count and the time of the
+// most recent failure, for surfacing retry/backoff posture through the
+// control API's GET /debug/connections.
+func (b *circuitBreaker) Stats() (fails int, lastFailureAt time.Time) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.fails, b.lastFailureAt
+}
@@ -0,0 +1,67 @@
+package clients
+
+import (
+	"errors"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/mtk14m/notilius/platform-api/internal/middleware"
+)
+
+// ErrCircuitOpen is returned when a call is rejected because the circuit
+// breaker for its endpoint is open.
+var ErrCircuitOpen = errors.New("clients: circuit breaker is open")
+
+// ErrRetryBudgetExhausted is returned when a call would retry but the
+// endpoint's retry budget has no tokens left.
+var ErrRetryBudgetExhausted = errors.New("clients: retry budget exhausted")
+
+// ToAppError maps a gRPC (or breaker/budget) error from the provisioning
+// service to the *middleware.AppError the HTTP layer expects, so handlers
+// don't need to know this client talks gRPC at all.
+func ToAppError(err error) *middleware.AppError {
+	if err == nil {
+		return nil
+	}
+
+	if errors.Is(err, ErrCircuitOpen) || errors.Is(err, ErrRetryBudgetExhausted) {
+		return middleware.NewServiceUnavailableError("provisioning service is temporarily unavailable")
+	}
+
+	st, ok := status.FromError(err)
+	if !ok {
+		return middleware.NewInternalError(err)
+	}
+
+	switch st.Code() {
+	case codes.InvalidArgument:
+		return middleware.NewValidationError("request", st.Message())
+	case codes.NotFound:
+		return middleware.NewNotFoundError(st.Message())
+	case codes.AlreadyExists:
+		return middleware.NewConflictError(st.Message())
+	case codes.Unauthenticated:
+		return middleware.NewUnauthorizedError(st.Message())
+	case codes.PermissionDenied:
+		return middleware.NewForbiddenError(st.Message())
+	case codes.Unavailable:
+		return middleware.NewServiceUnavailableError(st.Message())
+	case codes.DeadlineExceeded:
+		return middleware.NewTimeoutError(st.Message())
+	default:
+		return middleware.NewInternalError(err)
+	}
+}
+
+// isRetryableCode reports whether a gRPC status code should be retried.
+// Anything that indicates the request itself was wrong (InvalidArgument,
+// NotFound, ...) is never retried; only transient availability problems are.
+func isRetryableCode(code codes.Code) bool {
+	switch code {
+	case codes.Unavailable, codes.DeadlineExceeded:
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,27 @@
+package clients
+
+import "time"
+
+// ConnectionInspector is implemented by resilient clients (e.g.
+// ProvisioningClient) that want their pooled connections and retry/backoff
+// state surfaced through the control API's GET /debug/connections.
+type ConnectionInspector interface {
+	Name() string
+	ConnectionSnapshot() []ConnectionState
+}
+
+// ConnectionState describes one pooled connection's retry/backoff posture.
+type ConnectionState struct {
+	Endpoint      string        `json:"endpoint"`
+	State         string        `json:"state"` // e.g. READY, CONNECTING, TRANSIENT_FAILURE
+	BreakerState  string        `json:"breakerState"`
+	RetryCount    int           `json:"retryCount"`
+	NextBackoff   time.Duration `json:"nextBackoff"`
+	LastFailureAt time.Time     `json:"lastFailureAt,omitempty"`
+}
+
+// CircuitResetter resets a named circuit breaker back to closed, used by the
+// control API's POST /debug/circuit/{service}/reset.
+type CircuitResetter interface {
+	ResetCircuit(endpoint string) error
+}
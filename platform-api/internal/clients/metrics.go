@@ -0,0 +1,71 @@
+package clients
+
+import "github.com/prometheus/client_golang/prometheus"
+
+// clientMetrics are the Prometheus series exposed for a single resilient
+// client (one set per service name, e.g. "provisioning").
+type clientMetrics struct {
+	requests     prometheus.Counter
+	retries      prometheus.Counter
+	inflight     prometheus.Gauge
+	breakerState prometheus.Gauge
+	latency      prometheus.Histogram
+}
+
+func newClientMetrics(service string) *clientMetrics {
+	labels := prometheus.Labels{"service": service}
+
+	m := &clientMetrics{
+		requests: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace:   "platform_api",
+			Subsystem:   "grpc_client",
+			Name:        "requests_total",
+			Help:        "Total gRPC requests issued by this client.",
+			ConstLabels: labels,
+		}),
+		retries: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace:   "platform_api",
+			Subsystem:   "grpc_client",
+			Name:        "retries_total",
+			Help:        "Total retry attempts issued by this client.",
+			ConstLabels: labels,
+		}),
+		inflight: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace:   "platform_api",
+			Subsystem:   "grpc_client",
+			Name:        "inflight_requests",
+			Help:        "Requests currently in flight.",
+			ConstLabels: labels,
+		}),
+		breakerState: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace:   "platform_api",
+			Subsystem:   "grpc_client",
+			Name:        "circuit_breaker_state",
+			Help:        "Circuit breaker state: 0=closed, 1=half-open, 2=open.",
+			ConstLabels: labels,
+		}),
+		latency: prometheus.NewHistogram(prometheus.HistogramOpts{
+			Namespace:   "platform_api",
+			Subsystem:   "grpc_client",
+			Name:        "request_duration_seconds",
+			Help:        "gRPC request latency in seconds.",
+			ConstLabels: labels,
+			Buckets:     prometheus.DefBuckets,
+		}),
+	}
+
+	prometheus.MustRegister(m.requests, m.retries, m.inflight, m.breakerState, m.latency)
+
+	return m
+}
+
+func breakerStateValue(s breakerState) float64 {
+	switch s {
+	case breakerHalfOpen:
+		return 1
+	case breakerOpen:
+		return 2
+	default:
+		return 0
+	}
+}
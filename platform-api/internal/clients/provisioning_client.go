@@ -1,7 +1,22 @@
+// Package clients holds resilient gRPC clients for platform-api's upstream
+// services.
 package clients
 
 import (
+	"context"
+	"crypto/rand"
+	"fmt"
+	"math/big"
+	"sync/atomic"
 	"time"
+
+	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/backoff"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+
+	"github.com/mtk14m/notilius/platform-api/internal/config"
 )
 
 // RetryConfig for gRCP client with resilience
@@ -11,4 +26,209 @@ type RetryConfig struct {
 	MaxBackoff     time.Duration
 }
 
-// ProvisioningClient wraps gRPC client with resilience
+// ProvisioningClient wraps gRPC client with resilience: a pool of
+// multiplexed connections dispatched round-robin, exponential backoff with
+// full jitter on retryable errors, a per-endpoint retry budget to prevent
+// retry storms, and a circuit breaker that trips after repeated failures.
+type ProvisioningClient struct {
+	cfg     config.ProvisioningServiceConfig
+	target  string
+	conns   []*grpc.ClientConn
+	next    uint64
+	breaker *circuitBreaker
+	budget  *retryBudget
+	metrics *clientMetrics
+}
+
+// NewProvisioningClient creates cfg.MaxConnections lazily-connecting
+// connections to the provisioning service and returns a client ready to
+// invoke RPCs through Invoke. Dialing is non-blocking: a provisioning
+// service that's briefly unavailable at boot (e.g. a simultaneous rolling
+// restart) does not fail startup, it's handled like any other transient
+// failure by the breaker/retry path in Invoke once a call is actually made.
+func NewProvisioningClient(cfg config.ProvisioningServiceConfig) (*ProvisioningClient, error) {
+	if cfg.MaxConnections <= 0 {
+		return nil, fmt.Errorf("provisioning service: maxConnections must be > 0, got %d", cfg.MaxConnections)
+	}
+
+	target := fmt.Sprintf("%s:%d", cfg.Host, cfg.Port)
+
+	conns := make([]*grpc.ClientConn, 0, cfg.MaxConnections)
+	for i := 0; i < cfg.MaxConnections; i++ {
+		conn, err := dial(target, cfg.ConnectionTimeout)
+		if err != nil {
+			for _, c := range conns {
+				c.Close()
+			}
+			return nil, fmt.Errorf("creating connection to provisioning service %s: %w", target, err)
+		}
+		conns = append(conns, conn)
+	}
+
+	return &ProvisioningClient{
+		cfg:     cfg,
+		target:  target,
+		conns:   conns,
+		breaker: newCircuitBreaker(5, 30*time.Second),
+		budget:  newRetryBudget(10, 10*time.Second),
+		metrics: newClientMetrics("provisioning"),
+	}, nil
+}
+
+// dial creates a connection without blocking for it to become READY
+// (gRPC's default): it returns immediately and connects in the background,
+// so a target that's down at startup doesn't fail NewProvisioningClient.
+// connectTimeout is gRPC's MinConnectTimeout: the minimum time each
+// individual connection attempt gets (gRPC raises it to the current backoff
+// delay once that is longer). It does not bound this call.
+func dial(target string, connectTimeout time.Duration) (*grpc.ClientConn, error) {
+	return grpc.DialContext(context.Background(), target,
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithConnectParams(grpc.ConnectParams{
+			Backoff:           backoff.DefaultConfig,
+			MinConnectTimeout: connectTimeout,
+		}),
+		grpc.WithStatsHandler(otelgrpc.NewClientHandler()),
+	)
+}
+
+// Invoke calls method on a pooled connection, retrying retryable failures
+// (Unavailable, DeadlineExceeded) with exponential backoff and full jitter
+// up to cfg.MaxRetries times, subject to the circuit breaker and retry
+// budget. InvalidArgument/NotFound and other terminal errors are never
+// retried.
+func (c *ProvisioningClient) Invoke(ctx context.Context, method string, req, reply interface{}, opts ...grpc.CallOption) error {
+	var lastErr error
+
+	for attempt := 0; attempt <= c.cfg.MaxRetries; attempt++ {
+		if !c.breaker.Allow() {
+			return ErrCircuitOpen
+		}
+
+		if attempt > 0 {
+			if !c.budget.Take() {
+				return ErrRetryBudgetExhausted
+			}
+			if err := c.sleepBackoff(ctx, attempt); err != nil {
+				return err
+			}
+			c.metrics.retries.Inc()
+		}
+
+		conn := c.nextConn()
+		c.metrics.inflight.Inc()
+		start := time.Now()
+		err := conn.Invoke(ctx, method, req, reply, opts...)
+		c.metrics.latency.Observe(time.Since(start).Seconds())
+		c.metrics.inflight.Dec()
+		c.metrics.requests.Inc()
+
+		if err == nil {
+			c.breaker.RecordSuccess()
+			c.metrics.breakerState.Set(breakerStateValue(c.breaker.State()))
+			return nil
+		}
+
+		lastErr = err
+		if !isRetryableCode(status.Code(err)) {
+			// The server responded; this particular request was simply
+			// invalid, so it shouldn't count against the breaker.
+			c.breaker.RecordSuccess()
+			c.metrics.breakerState.Set(breakerStateValue(c.breaker.State()))
+			return err
+		}
+
+		c.breaker.RecordFailure()
+		c.metrics.breakerState.Set(breakerStateValue(c.breaker.State()))
+	}
+
+	return lastErr
+}
+
+func (c *ProvisioningClient) nextConn() *grpc.ClientConn {
+	idx := atomic.AddUint64(&c.next, 1)
+	return c.conns[idx%uint64(len(c.conns))]
+}
+
+func (c *ProvisioningClient) sleepBackoff(ctx context.Context, attempt int) error {
+	timer := time.NewTimer(jitter(backoffForAttempt(c.cfg, attempt)))
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// backoffForAttempt returns the un-jittered backoff duration for the given
+// 1-based retry attempt, capped at cfg.MaxBackoff.
+func backoffForAttempt(cfg config.ProvisioningServiceConfig, attempt int) time.Duration {
+	backoff := cfg.InitialBackoff << uint(attempt-1)
+	if backoff <= 0 || backoff > cfg.MaxBackoff {
+		backoff = cfg.MaxBackoff
+	}
+	return backoff
+}
+
+// jitter returns a uniformly random duration in [0, max) ("full jitter").
+func jitter(max time.Duration) time.Duration {
+	if max <= 0 {
+		return 0
+	}
+	n, err := rand.Int(rand.Reader, big.NewInt(int64(max)))
+	if err != nil {
+		return max
+	}
+	return time.Duration(n.Int64())
+}
+
+// Close tears down all pooled connections.
+func (c *ProvisioningClient) Close() error {
+	var lastErr error
+	for _, conn := range c.conns {
+		if err := conn.Close(); err != nil {
+			lastErr = err
+		}
+	}
+	return lastErr
+}
+
+// Name identifies this client for the control API (clients.ConnectionInspector).
+func (c *ProvisioningClient) Name() string {
+	return "provisioning"
+}
+
+// ConnectionSnapshot implements clients.ConnectionInspector for the control
+// API's GET /debug/connections.
+func (c *ProvisioningClient) ConnectionSnapshot() []ConnectionState {
+	state := c.breaker.State().String()
+	fails, lastFailureAt := c.breaker.Stats()
+
+	var nextBackoff time.Duration
+	if fails > 0 {
+		nextBackoff = backoffForAttempt(c.cfg, fails)
+	}
+
+	states := make([]ConnectionState, len(c.conns))
+	for i, conn := range c.conns {
+		states[i] = ConnectionState{
+			Endpoint:      c.target,
+			State:         conn.GetState().String(),
+			BreakerState:  state,
+			RetryCount:    fails,
+			NextBackoff:   nextBackoff,
+			LastFailureAt: lastFailureAt,
+		}
+	}
+	return states
+}
+
+// ResetCircuit implements clients.CircuitResetter for the control API's
+// POST /debug/circuit/{service}/reset.
+func (c *ProvisioningClient) ResetCircuit(endpoint string) error {
+	c.breaker.Reset()
+	c.metrics.breakerState.Set(breakerStateValue(c.breaker.State()))
+	return nil
+}
@@ -0,0 +1,44 @@
+package clients
+
+import (
+	"math"
+	"sync"
+	"time"
+)
+
+// retryBudget is a token bucket limiting how many retries an endpoint can
+// spend in a given window, preventing a struggling upstream from being
+// hammered by retry storms. maxTokens refills continuously at
+// maxTokens/per.
+type retryBudget struct {
+	mu         sync.Mutex
+	tokens     float64
+	maxTokens  float64
+	refillRate float64 // tokens per second
+	lastRefill time.Time
+}
+
+func newRetryBudget(maxTokens int, per time.Duration) *retryBudget {
+	return &retryBudget{
+		tokens:     float64(maxTokens),
+		maxTokens:  float64(maxTokens),
+		refillRate: float64(maxTokens) / per.Seconds(),
+		lastRefill: time.Now(),
+	}
+}
+
+// Take consumes one retry token, returning false if the budget is exhausted.
+func (b *retryBudget) Take() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	now := time.Now()
+	b.tokens = math.Min(b.maxTokens, b.tokens+now.Sub(b.lastRefill).Seconds()*b.refillRate)
+	b.lastRefill = now
+
+	if b.tokens < 1 {
+		return false
+	}
+	b.tokens--
+	return true
+}
@@ -0,0 +1,267 @@
+package config
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/knadh/koanf/parsers/json"
+	"github.com/knadh/koanf/parsers/toml"
+	"github.com/knadh/koanf/parsers/yaml"
+	"github.com/knadh/koanf/providers/confmap"
+	"github.com/knadh/koanf/providers/env"
+	"github.com/knadh/koanf/providers/file"
+	"github.com/knadh/koanf/v2"
+)
+
+// EnvPrefix is the prefix environment overrides must carry, e.g.
+// PLATFORM_SERVER_PORT maps to the "server.port" key.
+const EnvPrefix = "PLATFORM_"
+
+// Source is a koanf provider: one layer in the config stack. Exported so
+// callers (tests, tools) can build custom layering with LoadWithOverrides
+// using confmap.Provider, file.Provider, env.Provider, etc.
+type Source = koanf.Provider
+
+// Load builds the configuration by layering, in order: built-in defaults,
+// the file named by the CONFIG_FILE env var (if set, format inferred from
+// its extension: .yaml/.yml/.toml/.json), and PLATFORM_-prefixed
+// environment overrides. Env overrides always win.
+//
+// It exits the process on a malformed config file or env var, matching the
+// fail-fast style the rest of main() uses for invalid configuration.
+func Load() *Config {
+	k := koanf.New(".")
+
+	if err := k.Load(confmap.Provider(defaults(), "."), nil); err != nil {
+		slog.Error("Failed to load default configuration", "error", err)
+		os.Exit(1)
+	}
+
+	path := os.Getenv("CONFIG_FILE")
+	if path != "" {
+		if err := loadFileInto(k, path); err != nil {
+			slog.Error("Failed to load config file", "path", path, "error", err)
+			os.Exit(1)
+		}
+		slog.Info("Loaded configuration file", "path", path)
+	}
+
+	if err := k.Load(envProvider(), nil); err != nil {
+		slog.Error("Failed to load environment overrides", "error", err)
+		os.Exit(1)
+	}
+
+	cfg, err := unmarshal(k)
+	if err != nil {
+		slog.Error("Failed to unmarshal configuration", "error", err)
+		os.Exit(1)
+	}
+	cfg.sourcePath = path
+
+	return cfg
+}
+
+// LoadFromFile builds the configuration from built-in defaults, the given
+// file, and PLATFORM_-prefixed environment overrides, returning an error
+// instead of exiting so callers can decide how to handle a bad file (e.g.
+// during a hot-reload or in tests).
+func LoadFromFile(path string) (*Config, error) {
+	k := koanf.New(".")
+
+	if err := k.Load(confmap.Provider(defaults(), "."), nil); err != nil {
+		return nil, fmt.Errorf("loading defaults: %w", err)
+	}
+	if err := loadFileInto(k, path); err != nil {
+		return nil, fmt.Errorf("loading config file %s: %w", path, err)
+	}
+	if err := k.Load(envProvider(), nil); err != nil {
+		return nil, fmt.Errorf("loading environment overrides: %w", err)
+	}
+
+	cfg, err := unmarshal(k)
+	if err != nil {
+		return nil, err
+	}
+	cfg.sourcePath = path
+
+	return cfg, nil
+}
+
+// LoadWithOverrides builds the configuration from built-in defaults followed
+// by the given sources, applied in order (later sources win). Load and
+// LoadFromFile build their own layer stack and do not call it; it's exposed
+// for callers that need custom layering, e.g. tests merging an in-memory
+// confmap.Provider on top of a fixture file. The returned Config records no
+// source file, so OnChange on it watches nothing.
+func LoadWithOverrides(sources ...Source) (*Config, error) {
+	k := koanf.New(".")
+
+	if err := k.Load(confmap.Provider(defaults(), "."), nil); err != nil {
+		return nil, fmt.Errorf("loading defaults: %w", err)
+	}
+	for _, src := range sources {
+		if err := k.Load(src, nil); err != nil {
+			return nil, fmt.Errorf("loading config source: %w", err)
+		}
+	}
+
+	return unmarshal(k)
+}
+
+// OnChange watches the file this Config was loaded from (if any) and calls
+// fn with a freshly reloaded Config whenever it changes on disk. It's a
+// no-op returning an empty stop func when the config wasn't loaded from a file
+// (e.g. env-only deployments). Callers should defer the returned stop func.
+func (c *Config) OnChange(fn func(*Config)) (stop func(), err error) {
+	if c.sourcePath == "" {
+		return func() {}, nil
+	}
+
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, fmt.Errorf("creating config watcher: %w", err)
+	}
+
+	// Watch the directory, not the file: editors typically replace the file
+	// (rename+create) rather than writing in place, which a direct file
+	// watch would miss after the first event.
+	if err := watcher.Add(filepath.Dir(c.sourcePath)); err != nil {
+		watcher.Close()
+		return nil, fmt.Errorf("watching config directory: %w", err)
+	}
+
+	done := make(chan struct{})
+	go func() {
+		defer watcher.Close()
+		for {
+			select {
+			case event, ok := <-watcher.Events:
+				if !ok {
+					return
+				}
+				if filepath.Clean(event.Name) != filepath.Clean(c.sourcePath) {
+					continue
+				}
+				if event.Op&(fsnotify.Write|fsnotify.Create) == 0 {
+					continue
+				}
+
+				reloaded, err := LoadFromFile(c.sourcePath)
+				if err != nil {
+					slog.Error("Config reload failed", "path", c.sourcePath, "error", err)
+					continue
+				}
+				slog.Info("Config reloaded", "path", c.sourcePath)
+				fn(reloaded)
+			case watchErr, ok := <-watcher.Errors:
+				if !ok {
+					return
+				}
+				slog.Error("Config watcher error", "error", watchErr)
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	return func() { close(done) }, nil
+}
+
+func loadFileInto(k *koanf.Koanf, path string) error {
+	parser, err := parserFor(path)
+	if err != nil {
+		return err
+	}
+	return k.Load(file.Provider(path), parser)
+}
+
+func parserFor(path string) (koanf.Parser, error) {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		return yaml.Parser(), nil
+	case ".toml":
+		return toml.Parser(), nil
+	case ".json":
+		return json.Parser(), nil
+	default:
+		return nil, fmt.Errorf("unsupported config file extension: %s", filepath.Ext(path))
+	}
+}
+
+// envProvider maps PLATFORM_SERVER_PORT -> "server.port", turning "_" into
+// the "." nesting delimiter and then matching case-insensitively against
+// the known (camelCase) keys from defaults() so the result lines up with
+// the `koanf` struct tags in config.go regardless of env var casing.
+func envProvider() *env.Env {
+	lookup := envKeyLookup()
+	return env.Provider(EnvPrefix, ".", func(s string) string {
+		s = strings.TrimPrefix(s, EnvPrefix)
+		key := strings.ReplaceAll(strings.ToLower(s), "_", ".")
+		if canonical, ok := lookup[key]; ok {
+			return canonical
+		}
+		return key
+	})
+}
+
+// envKeyLookup maps every known config key, lower-cased, to its canonical
+// (camelCase) form, so envProvider's transform can recover "maxRetries"
+// from an env var that can only ever spell it "MAXRETRIES".
+func envKeyLookup() map[string]string {
+	d := defaults()
+	lookup := make(map[string]string, len(d))
+	for key := range d {
+		lookup[strings.ToLower(key)] = key
+	}
+	return lookup
+}
+
+func unmarshal(k *koanf.Koanf) (*Config, error) {
+	var cfg Config
+	if err := k.Unmarshal("", &cfg); err != nil {
+		return nil, err
+	}
+	return &cfg, nil
+}
+
+// defaults mirrors the zero-config behavior the old env-var-only Load used,
+// as the base layer every other source is merged on top of.
+func defaults() map[string]interface{} {
+	return map[string]interface{}{
+		"server.port":           8000,
+		"server.addr":           "0.0.0.0",
+		"server.readTimeout":    "15s",
+		"server.writeTimeout":   "15s",
+		"server.idleTimeout":    "60s",
+		"server.maxHeaderBytes": 1 << 20, // 1MB
+
+		"provisioningService.host":              "localhost",
+		"provisioningService.port":              50051,
+		"provisioningService.maxConnections":    100,
+		"provisioningService.connectionTimeout": "5s",
+		"provisioningService.requestTimeout":    "30s",
+		"provisioningService.maxRetries":        3,
+		"provisioningService.initialBackoff":    "100ms",
+		"provisioningService.maxBackoff":        "10s",
+
+		"observability.logLevel":           "info",
+		"observability.logFormat":          "json",
+		"observability.tracingEnabled":     false,
+		"observability.tracingServiceName": "platform-api",
+		"observability.tracingEndpoint":    "http://localhost:4317",
+		"observability.metricsEnabled":     true,
+		"observability.metricsPort":        9090,
+		"observability.controlApiPort":     0,
+
+		"auth.enabled":       false,
+		"auth.issuerURL":     "",
+		"auth.clientID":      "",
+		"auth.clientSecret":  "",
+		"auth.jwtAudience":   "platform-api",
+		"auth.tokenCacheTTL": "5m",
+	}
+}
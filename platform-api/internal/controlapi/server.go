@@ -0,0 +1,199 @@
+// Package controlapi exposes a small, loopback-by-default HTTP surface for
+// live introspection and control of a running platform-api process, in the
+// spirit of Clash's external controller: current config, in-flight gRPC
+// connection/circuit-breaker state, runtime log level, and recently traced
+// requests.
+package controlapi
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"os"
+	"strconv"
+	"sync"
+
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+
+	"github.com/mtk14m/notilius/platform-api/internal/clients"
+	"github.com/mtk14m/notilius/platform-api/internal/config"
+	"github.com/mtk14m/notilius/platform-api/internal/middleware"
+)
+
+// Server is the control API. The zero value is not usable; construct with
+// NewServer.
+type Server struct {
+	cfg      *config.Config
+	logLevel *slog.LevelVar
+
+	mu         sync.RWMutex
+	inspectors map[string]clients.ConnectionInspector
+	resetters  map[string]clients.CircuitResetter
+	traces     *traceRingBuffer
+}
+
+// NewServer builds a control API bound to cfg's current settings. Register
+// gRPC clients afterwards via RegisterClient so their connection/circuit
+// state becomes visible.
+func NewServer(cfg *config.Config, logLevel *slog.LevelVar) *Server {
+	return &Server{
+		cfg:        cfg,
+		logLevel:   logLevel,
+		inspectors: make(map[string]clients.ConnectionInspector),
+		resetters:  make(map[string]clients.CircuitResetter),
+		traces:     newTraceRingBuffer(256),
+	}
+}
+
+// RegisterClient makes client's connection state and circuit breaker visible
+// under /debug/connections and /debug/circuit/{name}/reset.
+func (s *Server) RegisterClient(name string, inspector clients.ConnectionInspector, resetter clients.CircuitResetter) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.inspectors[name] = inspector
+	s.resetters[name] = resetter
+}
+
+// UpdateConfig swaps the config GET /debug/config serves, e.g. after
+// config.OnChange picks up a file change. Without this, the control API's
+// "current config" stays pinned to whatever was loaded at startup forever.
+func (s *Server) UpdateConfig(cfg *config.Config) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.cfg = cfg
+}
+
+// Listen builds the control API's http.Server, bound to the configured
+// CONTROL_API_PORT (or ObservabilityConfig.ControlAPIPort, falling back to
+// the metrics port when neither is set).
+func (s *Server) Listen() (*http.Server, error) {
+	port := s.cfg.Observability.ControlAPIPort
+	if raw := os.Getenv("CONTROL_API_PORT"); raw != "" {
+		p, err := strconv.Atoi(raw)
+		if err != nil {
+			return nil, fmt.Errorf("invalid CONTROL_API_PORT: %w", err)
+		}
+		port = p
+	}
+	if port == 0 {
+		port = s.cfg.Observability.MetricsPort
+	}
+
+	addr := fmt.Sprintf("127.0.0.1:%d", port)
+	handler := s.Handler()
+
+	if s.cfg.Auth.Enabled {
+		// OIDC gates every /debug request below, so it's safe to listen
+		// beyond loopback. /metrics is in middleware's no-auth paths and
+		// stays reachable without a token.
+		auth, err := middleware.NewAuthenticator(context.Background(), s.cfg.Auth)
+		if err != nil {
+			return nil, fmt.Errorf("initializing control API auth: %w", err)
+		}
+		handler = auth.HTTPHandler(handler)
+		addr = fmt.Sprintf("0.0.0.0:%d", port)
+	}
+
+	slog.Info("Control API listening", "addr", addr)
+
+	return &http.Server{
+		Addr:    addr,
+		Handler: handler,
+	}, nil
+}
+
+// Handler returns the routed http.Handler for the debug endpoints, plus
+// GET /metrics for Prometheus scraping when cfg.Observability.MetricsEnabled
+// (this server already shares the metrics port by default, see Listen).
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /debug/config", s.handleConfig)
+	mux.HandleFunc("GET /debug/connections", s.handleConnections)
+	mux.HandleFunc("POST /debug/loglevel", s.handleSetLogLevel)
+	mux.HandleFunc("GET /debug/traces/recent", s.handleRecentTraces)
+	mux.HandleFunc("POST /debug/circuit/{service}/reset", s.handleCircuitReset)
+	if s.cfg.Observability.MetricsEnabled {
+		mux.Handle("GET /metrics", promhttp.Handler())
+	}
+	return mux
+}
+
+func (s *Server) handleConfig(w http.ResponseWriter, r *http.Request) {
+	s.mu.RLock()
+	cfg := s.cfg
+	s.mu.RUnlock()
+	writeJSON(w, http.StatusOK, redact(cfg))
+}
+
+func (s *Server) handleConnections(w http.ResponseWriter, r *http.Request) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	out := make(map[string][]clients.ConnectionState, len(s.inspectors))
+	for name, inspector := range s.inspectors {
+		out[name] = inspector.ConnectionSnapshot()
+	}
+	writeJSON(w, http.StatusOK, out)
+}
+
+type logLevelRequest struct {
+	Level string `json:"level"`
+}
+
+func (s *Server) handleSetLogLevel(w http.ResponseWriter, r *http.Request) {
+	var req logLevelRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid request body"})
+		return
+	}
+
+	var level slog.Level
+	if err := level.UnmarshalText([]byte(req.Level)); err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "unknown log level: " + req.Level})
+		return
+	}
+
+	s.logLevel.Set(level)
+	slog.Info("Log level changed via control API", "level", level.String())
+	writeJSON(w, http.StatusOK, map[string]string{"level": level.String()})
+}
+
+func (s *Server) handleRecentTraces(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, s.traces.Snapshot())
+}
+
+func (s *Server) handleCircuitReset(w http.ResponseWriter, r *http.Request) {
+	service := r.PathValue("service")
+
+	s.mu.RLock()
+	resetter, ok := s.resetters[service]
+	s.mu.RUnlock()
+	if !ok {
+		writeJSON(w, http.StatusNotFound, map[string]string{"error": "unknown service: " + service})
+		return
+	}
+
+	if err := resetter.ResetCircuit(service); err != nil {
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": err.Error()})
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]string{"status": "reset"})
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+// redact returns a copy of cfg with secrets masked, safe to serve over
+// GET /debug/config.
+func redact(cfg *config.Config) config.Config {
+	redacted := *cfg
+	if redacted.Auth.ClientSecret != "" {
+		redacted.Auth.ClientSecret = "REDACTED"
+	}
+	return redacted
+}
@@ -0,0 +1,85 @@
+package controlapi
+
+import (
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/mtk14m/notilius/platform-api/internal/middleware"
+)
+
+// TraceRecord is one entry in the recent-traces ring buffer.
+type TraceRecord struct {
+	TraceID  string        `json:"traceId"`
+	Method   string        `json:"method"`
+	Path     string        `json:"path"`
+	Status   int           `json:"status"`
+	Duration time.Duration `json:"duration"`
+	At       time.Time     `json:"at"`
+}
+
+// traceRingBuffer keeps the last N TraceRecords for GET /debug/traces/recent.
+type traceRingBuffer struct {
+	mu      sync.Mutex
+	records []TraceRecord
+	next    int
+	filled  bool
+}
+
+func newTraceRingBuffer(size int) *traceRingBuffer {
+	return &traceRingBuffer{records: make([]TraceRecord, size)}
+}
+
+func (b *traceRingBuffer) add(rec TraceRecord) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.records[b.next] = rec
+	b.next = (b.next + 1) % len(b.records)
+	if b.next == 0 {
+		b.filled = true
+	}
+}
+
+// Snapshot returns the buffered records, most recent first.
+func (b *traceRingBuffer) Snapshot() []TraceRecord {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	n := b.next
+	if !b.filled {
+		out := make([]TraceRecord, n)
+		for i := 0; i < n; i++ {
+			out[i] = b.records[n-1-i]
+		}
+		return out
+	}
+
+	size := len(b.records)
+	out := make([]TraceRecord, size)
+	for i := 0; i < size; i++ {
+		out[i] = b.records[(n-1-i+size)%size]
+	}
+	return out
+}
+
+// TraceRecorderMiddleware records each request's trace ID, route, status and
+// duration into the control API's recent-traces ring buffer. The trace ID
+// comes from middleware.TraceIDFromContext on the request context, which
+// middleware.TracingMiddleware or middleware.TraceIDMiddleware sets.
+func (s *Server) TraceRecorderMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+		c.Next()
+
+		s.traces.add(TraceRecord{
+			TraceID:  middleware.TraceIDFromContext(c.Request.Context()),
+			Method:   c.Request.Method,
+			Path:     c.Request.URL.Path,
+			Status:   c.Writer.Status(),
+			Duration: time.Since(start),
+			At:       start,
+		})
+	}
+}
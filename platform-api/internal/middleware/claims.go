@@ -0,0 +1,79 @@
+package middleware
+
+import (
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// claimsContextKey is the gin context key OIDCAuth stores validated Claims
+// under.
+const claimsContextKey = "claims"
+
+// Claims is the validated identity attached to the gin context by OIDCAuth.
+type Claims struct {
+	Subject string
+	Email   string
+	Roles   []string
+	Raw     map[string]any
+}
+
+// ClaimsFromContext returns the Claims OIDCAuth attached to c, if any.
+func ClaimsFromContext(c *gin.Context) (Claims, bool) {
+	v, ok := c.Get(claimsContextKey)
+	if !ok {
+		return Claims{}, false
+	}
+	claims, ok := v.(Claims)
+	return claims, ok
+}
+
+func (c Claims) hasRole(role string) bool {
+	for _, r := range c.Roles {
+		if r == role {
+			return true
+		}
+	}
+	return false
+}
+
+func (c Claims) hasScope(scope string) bool {
+	raw, ok := c.Raw["scope"].(string)
+	if !ok {
+		return false
+	}
+	for _, s := range strings.Fields(raw) {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
+
+// RequireRole rejects the request with 401 unless the authenticated
+// principal's roles include role. Mount after OIDCAuth.
+func RequireRole(role string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		claims, ok := ClaimsFromContext(c)
+		if !ok || !claims.hasRole(role) {
+			c.Error(NewUnauthorizedError("missing required role: " + role))
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
+
+// RequireScope rejects the request with 401 unless the authenticated
+// token's "scope" claim includes scope. Mount after OIDCAuth.
+func RequireScope(scope string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		claims, ok := ClaimsFromContext(c)
+		if !ok || !claims.hasScope(scope) {
+			c.Error(NewUnauthorizedError("missing required scope: " + scope))
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
@@ -76,6 +76,30 @@ func NewUnauthorizedError(reason string) *AppError {
 	}
 }
 
+func NewForbiddenError(reason string) *AppError {
+	return &AppError{
+		Code:       "FORBIDDEN",
+		Message:    reason,
+		StatusCode: http.StatusForbidden,
+	}
+}
+
+func NewServiceUnavailableError(reason string) *AppError {
+	return &AppError{
+		Code:       "SERVICE_UNAVAILABLE",
+		Message:    reason,
+		StatusCode: http.StatusServiceUnavailable,
+	}
+}
+
+func NewTimeoutError(reason string) *AppError {
+	return &AppError{
+		Code:       "TIMEOUT",
+		Message:    reason,
+		StatusCode: http.StatusGatewayTimeout,
+	}
+}
+
 // ErrorHandlerMiddleware catches panics and errors
 func ErrorHandlerMiddleware() gin.HandlerFunc {
 	return func(c *gin.Context) {
@@ -144,10 +168,7 @@ func handleError(c *gin.Context) {
 
 // Helper to get trace ID
 func getTraceID(c *gin.Context) string {
-	if traceID, ok := c.Get("traceId"); ok {
-		return traceID.(string)
-	}
-	return ""
+	return TraceIDFromContext(c.Request.Context())
 }
 
 // RequestLogger creates a logger instance for request handling
@@ -0,0 +1,183 @@
+package middleware
+
+import (
+	"context"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+type discoveryDocument struct {
+	Issuer  string `json:"issuer"`
+	JWKSURI string `json:"jwks_uri"`
+}
+
+type jsonWebKey struct {
+	Kid string `json:"kid"`
+	Kty string `json:"kty"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+}
+
+type jsonWebKeySet struct {
+	Keys []jsonWebKey `json:"keys"`
+}
+
+// discoverIssuer fetches issuerURL's OIDC discovery document.
+func discoverIssuer(ctx context.Context, httpClient *http.Client, issuerURL string) (*discoveryDocument, error) {
+	endpoint := strings.TrimSuffix(issuerURL, "/") + "/.well-known/openid-configuration"
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return nil, fmt.Errorf("building discovery request: %w", err)
+	}
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("fetching discovery document: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("discovery endpoint %s returned %d", endpoint, resp.StatusCode)
+	}
+
+	var doc discoveryDocument
+	if err := json.NewDecoder(resp.Body).Decode(&doc); err != nil {
+		return nil, fmt.Errorf("decoding discovery document: %w", err)
+	}
+	return &doc, nil
+}
+
+// jwksCache caches an issuer's RSA signing keys by kid, refreshed in the
+// background every ttl. Request-path validation only blocks on a network
+// call when a kid is not in the cache (see Key), which includes the first
+// fetch.
+type jwksCache struct {
+	jwksURL    string
+	httpClient *http.Client
+	ttl        time.Duration
+
+	mu          sync.RWMutex
+	keys        map[string]*rsa.PublicKey
+	lastRefresh time.Time
+}
+
+// minRefreshInterval debounces on-demand refreshes triggered by an unknown
+// kid, so a flood of requests signed with a bad/unrecognized key can't turn
+// into a refresh storm against the IdP.
+const minRefreshInterval = 5 * time.Second
+
+func newJWKSCache(jwksURL string, ttl time.Duration, httpClient *http.Client) *jwksCache {
+	return &jwksCache{
+		jwksURL:    jwksURL,
+		httpClient: httpClient,
+		ttl:        ttl,
+		keys:       make(map[string]*rsa.PublicKey),
+	}
+}
+
+// refresh fetches and replaces the cached key set.
+func (j *jwksCache) refresh(ctx context.Context) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, j.jwksURL, nil)
+	if err != nil {
+		return fmt.Errorf("building jwks request: %w", err)
+	}
+
+	resp, err := j.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("fetching jwks: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("jwks endpoint %s returned %d", j.jwksURL, resp.StatusCode)
+	}
+
+	var set jsonWebKeySet
+	if err := json.NewDecoder(resp.Body).Decode(&set); err != nil {
+		return fmt.Errorf("decoding jwks: %w", err)
+	}
+
+	keys := make(map[string]*rsa.PublicKey, len(set.Keys))
+	for _, jwk := range set.Keys {
+		if jwk.Kty != "RSA" {
+			continue
+		}
+		key, err := jwk.rsaPublicKey()
+		if err != nil {
+			continue
+		}
+		keys[jwk.Kid] = key
+	}
+
+	j.mu.Lock()
+	j.keys = keys
+	j.lastRefresh = time.Now()
+	j.mu.Unlock()
+
+	return nil
+}
+
+// startBackgroundRefresh refreshes the cache every ttl until ctx is done.
+func (j *jwksCache) startBackgroundRefresh(ctx context.Context) {
+	ticker := time.NewTicker(j.ttl)
+	go func() {
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				_ = j.refresh(ctx)
+			}
+		}
+	}()
+}
+
+// Key returns the cached public key for kid, forcing a refresh when kid
+// isn't in the cache (e.g. the IdP just rotated its signing key), debounced
+// by minRefreshInterval so a run of tokens signed with an unknown kid can't
+// hammer the JWKS endpoint.
+func (j *jwksCache) Key(ctx context.Context, kid string) (*rsa.PublicKey, error) {
+	j.mu.RLock()
+	key, ok := j.keys[kid]
+	sinceRefresh := time.Since(j.lastRefresh)
+	j.mu.RUnlock()
+
+	if !ok && sinceRefresh >= minRefreshInterval {
+		if err := j.refresh(ctx); err != nil {
+			return nil, err
+		}
+		j.mu.RLock()
+		key, ok = j.keys[kid]
+		j.mu.RUnlock()
+	}
+
+	if !ok {
+		return nil, fmt.Errorf("no signing key found for kid %q", kid)
+	}
+	return key, nil
+}
+
+func (jwk jsonWebKey) rsaPublicKey() (*rsa.PublicKey, error) {
+	nBytes, err := base64.RawURLEncoding.DecodeString(jwk.N)
+	if err != nil {
+		return nil, fmt.Errorf("decoding modulus: %w", err)
+	}
+	eBytes, err := base64.RawURLEncoding.DecodeString(jwk.E)
+	if err != nil {
+		return nil, fmt.Errorf("decoding exponent: %w", err)
+	}
+
+	return &rsa.PublicKey{
+		N: new(big.Int).SetBytes(nBytes),
+		E: int(new(big.Int).SetBytes(eBytes).Int64()),
+	}, nil
+}
@@ -0,0 +1,244 @@
+package middleware
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+
+	"github.com/mtk14m/notilius/platform-api/internal/config"
+)
+
+// discoveryTimeout bounds how long NewAuthenticator will wait on the
+// issuer's discovery document before giving up, so a hung IdP can't block
+// process startup indefinitely.
+const discoveryTimeout = 10 * time.Second
+
+// noAuthPaths are never gated behind OIDCAuth regardless of cfg.Enabled.
+var noAuthPaths = map[string]bool{
+	"/health":  true,
+	"/metrics": true,
+}
+
+type validatedToken struct {
+	claims    Claims
+	expiresAt time.Time
+}
+
+// Authenticator validates bearer tokens against an OIDC issuer: it
+// discovers the issuer's JWKS endpoint, caches signing keys for
+// cfg.TokenCacheTTL with background refresh, and verifies
+// signature/iss/aud/exp/nbf of each token. Successful validations are
+// themselves cached by token hash for cfg.TokenCacheTTL so a hot token
+// isn't re-verified on every request. Shared by OIDCAuth (gin) and the
+// control API's loopback-bypass gating.
+type Authenticator struct {
+	cfg         config.AuthConfig
+	jwks        *jwksCache
+	validations *validationCache
+}
+
+// NewAuthenticator discovers cfg.IssuerURL's JWKS endpoint and starts the
+// background key refresh. Returns an error if discovery fails.
+func NewAuthenticator(ctx context.Context, cfg config.AuthConfig) (*Authenticator, error) {
+	discoveryCtx, cancel := context.WithTimeout(ctx, discoveryTimeout)
+	defer cancel()
+
+	doc, err := discoverIssuer(discoveryCtx, http.DefaultClient, cfg.IssuerURL)
+	if err != nil {
+		return nil, fmt.Errorf("discovering OIDC issuer %s: %w", cfg.IssuerURL, err)
+	}
+
+	jwks := newJWKSCache(doc.JWKSURI, cfg.TokenCacheTTL, http.DefaultClient)
+	jwks.startBackgroundRefresh(ctx)
+
+	return &Authenticator{
+		cfg:         cfg,
+		jwks:        jwks,
+		validations: newValidationCache(cfg.TokenCacheTTL),
+	}, nil
+}
+
+// Authenticate validates token and returns its Claims.
+func (a *Authenticator) Authenticate(ctx context.Context, token string) (Claims, error) {
+	key := tokenCacheKey(token)
+
+	if cached, ok := a.validations.Get(key); ok {
+		return cached.claims, nil
+	}
+
+	parsed, err := jwt.Parse(token, func(t *jwt.Token) (interface{}, error) {
+		kid, _ := t.Header["kid"].(string)
+		return a.jwks.Key(ctx, kid)
+	},
+		jwt.WithValidMethods([]string{"RS256"}),
+		jwt.WithIssuer(a.cfg.IssuerURL),
+		jwt.WithAudience(a.cfg.JWTAudience),
+		jwt.WithExpirationRequired(),
+	)
+	if err != nil {
+		return Claims{}, fmt.Errorf("invalid token: %w", err)
+	}
+
+	rawClaims, ok := parsed.Claims.(jwt.MapClaims)
+	if !ok {
+		return Claims{}, fmt.Errorf("invalid token: unexpected claims type")
+	}
+
+	claims := claimsFromJWT(rawClaims)
+
+	expiresAt := time.Now().Add(a.cfg.TokenCacheTTL)
+	if exp, err := parsed.Claims.GetExpirationTime(); err == nil && exp != nil && exp.Time.Before(expiresAt) {
+		expiresAt = exp.Time
+	}
+	a.validations.Set(key, validatedToken{claims: claims, expiresAt: expiresAt})
+
+	return claims, nil
+}
+
+// OIDCAuth validates bearer tokens against cfg's OIDC issuer on every gin
+// request, skipping /health and /metrics. Returns a passthrough handler
+// when cfg.Enabled is false, and a handler that rejects every request when
+// issuer discovery fails (fail closed, not open).
+func OIDCAuth(cfg config.AuthConfig) gin.HandlerFunc {
+	if !cfg.Enabled {
+		return func(c *gin.Context) { c.Next() }
+	}
+
+	auth, err := NewAuthenticator(context.Background(), cfg)
+	if err != nil {
+		slog.Error("OIDC discovery failed; auth middleware will reject all requests", "issuer", cfg.IssuerURL, "error", err)
+		return func(c *gin.Context) {
+			c.Error(NewUnauthorizedError("authentication is misconfigured"))
+			c.Abort()
+		}
+	}
+
+	return func(c *gin.Context) {
+		if noAuthPaths[c.Request.URL.Path] {
+			c.Next()
+			return
+		}
+
+		token, ok := bearerToken(c.GetHeader("Authorization"))
+		if !ok {
+			c.Error(NewUnauthorizedError("missing bearer token"))
+			c.Abort()
+			return
+		}
+
+		claims, err := auth.Authenticate(c.Request.Context(), token)
+		if err != nil {
+			c.Error(NewUnauthorizedError(err.Error()))
+			c.Abort()
+			return
+		}
+
+		c.Set(claimsContextKey, claims)
+		c.Next()
+	}
+}
+
+// HTTPHandler wraps next with the same bearer-token gating as OIDCAuth, for
+// callers that aren't on gin (e.g. internal/controlapi's plain net/http
+// mux).
+func (a *Authenticator) HTTPHandler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if noAuthPaths[r.URL.Path] {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		token, ok := bearerToken(r.Header.Get("Authorization"))
+		if !ok {
+			http.Error(w, "missing bearer token", http.StatusUnauthorized)
+			return
+		}
+
+		if _, err := a.Authenticate(r.Context(), token); err != nil {
+			http.Error(w, err.Error(), http.StatusUnauthorized)
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+func bearerToken(header string) (string, bool) {
+	token, ok := strings.CutPrefix(header, "Bearer ")
+	if !ok || token == "" {
+		return "", false
+	}
+	return token, true
+}
+
+func claimsFromJWT(raw jwt.MapClaims) Claims {
+	claims := Claims{Raw: raw}
+
+	if sub, ok := raw["sub"].(string); ok {
+		claims.Subject = sub
+	}
+	if email, ok := raw["email"].(string); ok {
+		claims.Email = email
+	}
+
+	switch roles := raw["roles"].(type) {
+	case []interface{}:
+		for _, r := range roles {
+			if s, ok := r.(string); ok {
+				claims.Roles = append(claims.Roles, s)
+			}
+		}
+	case []string:
+		claims.Roles = roles
+	}
+
+	return claims
+}
+
+func tokenCacheKey(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// validationCache holds successfully validated tokens, keyed by SHA-256 of
+// the raw token, so repeated requests on the same hot token skip signature
+// verification.
+type validationCache struct {
+	mu      sync.Mutex
+	entries map[string]validatedToken
+	ttl     time.Duration
+}
+
+func newValidationCache(ttl time.Duration) *validationCache {
+	return &validationCache{entries: make(map[string]validatedToken), ttl: ttl}
+}
+
+func (c *validationCache) Get(key string) (validatedToken, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry, ok := c.entries[key]
+	if !ok {
+		return validatedToken{}, false
+	}
+	if time.Now().After(entry.expiresAt) {
+		delete(c.entries, key)
+		return validatedToken{}, false
+	}
+	return entry, true
+}
+
+func (c *validationCache) Set(key string, entry validatedToken) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.entries[key] = entry
+}
@@ -0,0 +1,138 @@
+package middleware
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// traceIDContextKey is the fallback context key used when no OTel span is present,
+// e.g. tracing is disabled (config.ObservabilityConfig.TracingEnabled=false)
+// and the global TracerProvider is a no-op.
+type traceIDContextKey struct{}
+
+// WithTraceID attaches id to ctx. TraceIDFromContext prefers a live OTel span
+// context over this value, so WithTraceID only matters when tracing is
+// disabled or a span was never started for this request.
+func WithTraceID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, traceIDContextKey{}, id)
+}
+
+// TraceIDFromContext returns the trace ID correlating this request: the
+// active OTel span's trace ID if one exists, otherwise whatever was attached
+// via WithTraceID (typically by TraceIDMiddleware), otherwise "".
+func TraceIDFromContext(ctx context.Context) string {
+	if sc := trace.SpanContextFromContext(ctx); sc.HasTraceID() {
+		return sc.TraceID().String()
+	}
+	if id, ok := ctx.Value(traceIDContextKey{}).(string); ok {
+		return id
+	}
+	return ""
+}
+
+// GenerateTraceID returns a new random 16-byte (32 hex char) W3C-compliant
+// trace ID using crypto/rand. Unlike the old time.Now().UnixNano()-seeded
+// generator, every byte is independently random.
+func GenerateTraceID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("generating trace id: %w", err)
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// ParseTraceparent extracts the trace-id field from a W3C "traceparent"
+// header (format "version-traceid-parentid-flags", e.g.
+// "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"). It returns
+// ok=false for anything malformed or carrying the reserved all-zero trace ID.
+func ParseTraceparent(header string) (traceID string, ok bool) {
+	parts := strings.Split(header, "-")
+	if len(parts) != 4 {
+		return "", false
+	}
+	version, id, parentID, flags := parts[0], parts[1], parts[2], parts[3]
+
+	if len(version) != 2 || len(id) != 32 || len(parentID) != 16 || len(flags) != 2 {
+		return "", false
+	}
+	if !isHex(id) || !isHex(parentID) || !isHex(version) || !isHex(flags) {
+		return "", false
+	}
+	if allZero(id) {
+		return "", false
+	}
+	return id, true
+}
+
+// FormatTraceparent builds a "traceparent" header value for the given trace
+// ID. Used when this service originates the trace (no incoming header) so
+// downstream calls and the response still carry a valid one.
+func FormatTraceparent(traceID string) string {
+	return fmt.Sprintf("00-%s-%s-01", traceID, randomSpanIDHex())
+}
+
+func randomSpanIDHex() string {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		return "0000000000000000"
+	}
+	return hex.EncodeToString(b)
+}
+
+func isHex(s string) bool {
+	for _, r := range s {
+		if !((r >= '0' && r <= '9') || (r >= 'a' && r <= 'f') || (r >= 'A' && r <= 'F')) {
+			return false
+		}
+	}
+	return true
+}
+
+func allZero(s string) bool {
+	for _, r := range s {
+		if r != '0' {
+			return false
+		}
+	}
+	return true
+}
+
+// TraceIDMiddleware is the fallback correlation layer for when OTel tracing
+// is disabled (or otherwise produced no valid span, e.g. a no-op
+// TracerProvider): it honors an incoming traceparent header by reusing its
+// trace-id, otherwise generates a fresh one via crypto/rand, and always
+// emits a valid traceparent on the response. It is a no-op when
+// TracingMiddleware already started a real span for this request.
+func TraceIDMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx := c.Request.Context()
+
+		if sc := trace.SpanContextFromContext(ctx); sc.HasTraceID() {
+			c.Next()
+			return
+		}
+
+		traceID, ok := ParseTraceparent(c.GetHeader("traceparent"))
+		if !ok {
+			id, err := GenerateTraceID()
+			if err != nil {
+				// crypto/rand failure is effectively unrecoverable; better to
+				// keep serving with an inert ID than fail the request.
+				id = "00000000000000000000000000000000"
+			}
+			traceID = id
+		}
+
+		c.Request = c.Request.WithContext(WithTraceID(ctx, traceID))
+		c.Set("traceId", traceID)
+		c.Header("traceparent", FormatTraceparent(traceID))
+
+		c.Next()
+	}
+}
@@ -0,0 +1,121 @@
+package middleware
+
+import (
+	"context"
+	"sync"
+	"testing"
+)
+
+func TestParseTraceparent(t *testing.T) {
+	tests := []struct {
+		name   string
+		header string
+		wantID string
+		wantOK bool
+	}{
+		{
+			name:   "valid",
+			header: "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+			wantID: "4bf92f3577b34da6a3ce929d0e0e4736",
+			wantOK: true,
+		},
+		{
+			name:   "empty",
+			header: "",
+			wantOK: false,
+		},
+		{
+			name:   "wrong number of parts",
+			header: "00-4bf92f3577b34da6a3ce929d0e0e4736",
+			wantOK: false,
+		},
+		{
+			name:   "short trace id",
+			header: "00-abc-00f067aa0ba902b7-01",
+			wantOK: false,
+		},
+		{
+			name:   "non-hex trace id",
+			header: "00-zzzzzzzzzzzzzzzzzzzzzzzzzzzzzzzz-00f067aa0ba902b7-01",
+			wantOK: false,
+		},
+		{
+			name:   "all-zero trace id is reserved",
+			header: "00-00000000000000000000000000000000-00f067aa0ba902b7-01",
+			wantOK: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			id, ok := ParseTraceparent(tt.header)
+			if ok != tt.wantOK {
+				t.Fatalf("ParseTraceparent(%q) ok = %v, want %v", tt.header, ok, tt.wantOK)
+			}
+			if ok && id != tt.wantID {
+				t.Fatalf("ParseTraceparent(%q) id = %q, want %q", tt.header, id, tt.wantID)
+			}
+		})
+	}
+}
+
+func TestGenerateTraceIDUniqueUnderConcurrency(t *testing.T) {
+	const n = 500
+
+	ids := make(chan string, n)
+	var wg sync.WaitGroup
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			id, err := GenerateTraceID()
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			ids <- id
+		}()
+	}
+	wg.Wait()
+	close(ids)
+
+	seen := make(map[string]struct{}, n)
+	for id := range ids {
+		if len(id) != 32 {
+			t.Fatalf("GenerateTraceID() returned %q with length %d, want 32", id, len(id))
+		}
+		if _, dup := seen[id]; dup {
+			t.Fatalf("GenerateTraceID() produced duplicate id %q", id)
+		}
+		seen[id] = struct{}{}
+	}
+}
+
+func TestFormatTraceparentRoundTrips(t *testing.T) {
+	id, err := GenerateTraceID()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	header := FormatTraceparent(id)
+
+	got, ok := ParseTraceparent(header)
+	if !ok {
+		t.Fatalf("ParseTraceparent(%q) = false, want true", header)
+	}
+	if got != id {
+		t.Fatalf("round-tripped trace id = %q, want %q", got, id)
+	}
+}
+
+func TestWithTraceIDAndTraceIDFromContext(t *testing.T) {
+	ctx := context.Background()
+	if got := TraceIDFromContext(ctx); got != "" {
+		t.Fatalf("TraceIDFromContext(background) = %q, want empty", got)
+	}
+
+	ctx = WithTraceID(ctx, "deadbeefdeadbeefdeadbeefdeadbeef")
+	if got := TraceIDFromContext(ctx); got != "deadbeefdeadbeefdeadbeefdeadbeef" {
+		t.Fatalf("TraceIDFromContext() = %q, want %q", got, "deadbeefdeadbeefdeadbeefdeadbeef")
+	}
+}
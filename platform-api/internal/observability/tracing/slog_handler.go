@@ -0,0 +1,37 @@
+package tracing
+
+import (
+	"context"
+	"log/slog"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+// NewSlogHandler wraps next so that records logged with a context carrying an
+// active span are annotated with trace_id/span_id, without every call site
+// having to thread those fields through by hand.
+func NewSlogHandler(next slog.Handler) slog.Handler {
+	return &spanHandler{Handler: next}
+}
+
+type spanHandler struct {
+	slog.Handler
+}
+
+func (h *spanHandler) Handle(ctx context.Context, record slog.Record) error {
+	if sc := trace.SpanContextFromContext(ctx); sc.IsValid() {
+		record.AddAttrs(
+			slog.String("trace_id", sc.TraceID().String()),
+			slog.String("span_id", sc.SpanID().String()),
+		)
+	}
+	return h.Handler.Handle(ctx, record)
+}
+
+func (h *spanHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &spanHandler{Handler: h.Handler.WithAttrs(attrs)}
+}
+
+func (h *spanHandler) WithGroup(name string) slog.Handler {
+	return &spanHandler{Handler: h.Handler.WithGroup(name)}
+}
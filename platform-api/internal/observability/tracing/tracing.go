@@ -0,0 +1,86 @@
+// Package tracing wires up the OpenTelemetry SDK for platform-api: an
+// OTLP/gRPC exporter, a resource describing this service, and the W3C
+// propagators used to correlate spans across process boundaries.
+package tracing
+
+import (
+	"context"
+	"fmt"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.24.0"
+	"go.opentelemetry.io/otel/trace/noop"
+
+	"github.com/mtk14m/notilius/platform-api/internal/config"
+)
+
+// ServiceVersion is stamped into the tracing resource. Overridden at build
+// time via -ldflags "-X .../tracing.ServiceVersion=...".
+var ServiceVersion = "dev"
+
+// Provider owns the process-wide TracerProvider and its shutdown hook. The
+// zero value is a valid no-op provider.
+type Provider struct {
+	tp *sdktrace.TracerProvider
+}
+
+// NewProvider registers the global TracerProvider and the composite
+// traceparent+baggage propagator. When cfg.TracingEnabled is false it installs
+// a no-op TracerProvider so callers never have to branch on the setting
+// themselves; Shutdown is still safe to call in that case.
+func NewProvider(ctx context.Context, cfg config.ObservabilityConfig) (*Provider, error) {
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(
+		propagation.TraceContext{},
+		propagation.Baggage{},
+	))
+
+	if !cfg.TracingEnabled {
+		// An unconfigured sdktrace.TracerProvider is NOT a no-op: it still
+		// samples and creates real spans with valid trace/span IDs, which
+		// both burns CPU on every request and defeats the trace ID fallback
+		// (TraceIDMiddleware gates on HasTraceID()).
+		// trace/noop is a true no-op — Start always returns an invalid,
+		// non-recording span.
+		otel.SetTracerProvider(noop.NewTracerProvider())
+		return &Provider{}, nil
+	}
+
+	exporter, err := otlptracegrpc.New(ctx,
+		otlptracegrpc.WithEndpointURL(cfg.TracingEndpoint),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("creating otlp trace exporter: %w", err)
+	}
+
+	res, err := resource.New(ctx,
+		resource.WithAttributes(
+			semconv.ServiceName(cfg.TracingServiceName),
+			semconv.ServiceVersion(ServiceVersion),
+		),
+		resource.WithFromEnv(),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("building tracing resource: %w", err)
+	}
+
+	tp := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+	)
+	otel.SetTracerProvider(tp)
+
+	return &Provider{tp: tp}, nil
+}
+
+// Shutdown flushes buffered spans and stops the exporter. It is a no-op when
+// tracing was never enabled.
+func (p *Provider) Shutdown(ctx context.Context) error {
+	if p == nil || p.tp == nil {
+		return nil
+	}
+	return p.tp.Shutdown(ctx)
+}